@@ -17,6 +17,7 @@ import (
 var v1DumpMetaOptions = struct {
 	optionsV1
 	json bool
+	hcl  bool
 }{}
 
 var v1DumpMetaCommand = &cobra.Command{
@@ -31,10 +32,14 @@ var v1DumpMetaCommand = &cobra.Command{
 			return fmt.Errorf("error opening 1.x meta.db: %w", err)
 		}
 		meta := svc.meta
-		if !v1DumpMetaOptions.json {
+		switch {
+		case v1DumpMetaOptions.hcl:
+			return v1DumpMetaHCL(meta, os.Stdout)
+		case v1DumpMetaOptions.json:
+			return v1DumpMetaJSON(meta, os.Stdout)
+		default:
 			return v1DumpMetaText(meta, os.Stdout)
 		}
-		return v1DumpMetaJSON(meta, os.Stdout)
 	},
 }
 
@@ -162,4 +167,5 @@ func init() {
 
 	flags.StringVar(&v1DumpMetaOptions.metaDir, "v1-meta-dir", filepath.Join(v1dir, "meta"), "Path to meta.db directory")
 	flags.BoolVar(&v1DumpMetaOptions.json, "json", false, "json output")
+	flags.BoolVar(&v1DumpMetaOptions.hcl, "hcl", false, "Terraform HCL output")
 }
@@ -0,0 +1,144 @@
+package upgrade
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2/v1/services/meta"
+	"github.com/influxdata/influxql"
+)
+
+// v1DumpMetaHCL renders meta as Terraform configuration compatible with
+// the community influxdb provider: a "influxdb_database" resource per
+// database, an "influxdb_retention_policy" resource per retention
+// policy, and an "influxdb_user" resource per user with its privileges
+// rendered as "grant" blocks. Resources are emitted in a deterministic,
+// name-sorted order so that re-running the dump against a changed
+// meta.db produces a diff-friendly plan.
+func v1DumpMetaHCL(meta *meta.Client, out io.Writer) error {
+	dbs := meta.Databases()
+	sort.Slice(dbs, func(i, j int) bool { return dbs[i].Name < dbs[j].Name })
+
+	dbIdents := newHCLIdentSet()
+	rpIdents := newHCLIdentSet()
+
+	for _, db := range dbs {
+		dbLabel := dbIdents.next(db.Name)
+		fmt.Fprintf(out, "resource \"influxdb_database\" %q {\n", dbLabel)
+		fmt.Fprintf(out, "  name = %s\n", hclQuote(db.Name))
+		fmt.Fprintln(out, "}")
+		fmt.Fprintln(out)
+
+		rps := append([]meta.RetentionPolicyInfo(nil), db.RetentionPolicies...)
+		sort.Slice(rps, func(i, j int) bool { return rps[i].Name < rps[j].Name })
+
+		for _, rp := range rps {
+			rpLabel := rpIdents.next(db.Name + "_" + rp.Name)
+			fmt.Fprintf(out, "resource \"influxdb_retention_policy\" %q {\n", rpLabel)
+			fmt.Fprintf(out, "  name                 = %s\n", hclQuote(rp.Name))
+			fmt.Fprintf(out, "  database             = influxdb_database.%s.name\n", dbLabel)
+			fmt.Fprintf(out, "  duration             = %s\n", hclQuote(rp.Duration.String()))
+			fmt.Fprintf(out, "  shard_group_duration = %s\n", hclQuote(rp.ShardGroupDuration.String()))
+			if rp.Name == db.DefaultRetentionPolicy {
+				fmt.Fprintln(out, "  default              = true")
+			}
+			fmt.Fprintln(out, "}")
+			fmt.Fprintln(out)
+		}
+	}
+
+	users := meta.Users()
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+
+	userIdents := newHCLIdentSet()
+	for _, user := range users {
+		fmt.Fprintf(out, "resource \"influxdb_user\" %q {\n", userIdents.next(user.Name))
+		fmt.Fprintf(out, "  name  = %s\n", hclQuote(user.Name))
+		if user.Admin {
+			fmt.Fprintln(out, "  admin = true")
+		}
+
+		dbs := make([]string, 0, len(user.Privileges))
+		for db := range user.Privileges {
+			dbs = append(dbs, db)
+		}
+		sort.Strings(dbs)
+
+		for _, db := range dbs {
+			priv := user.Privileges[db]
+			if priv == influxql.NoPrivileges {
+				continue
+			}
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, "  grant {")
+			fmt.Fprintf(out, "    database  = %s\n", hclQuote(db))
+			fmt.Fprintf(out, "    privilege = %s\n", hclQuote(hclPrivilege(priv)))
+			fmt.Fprintln(out, "  }")
+		}
+
+		fmt.Fprintln(out, "}")
+		fmt.Fprintln(out)
+	}
+
+	return nil
+}
+
+func hclPrivilege(p influxql.Privilege) string {
+	switch p {
+	case influxql.ReadPrivilege:
+		return "READ"
+	case influxql.WritePrivilege:
+		return "WRITE"
+	case influxql.AllPrivileges:
+		return "ALL"
+	default:
+		return "NO PRIVILEGES"
+	}
+}
+
+var hclIdentDisallowed = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// hclIdent turns name into a valid, readable Terraform resource label.
+// Distinct names that differ only in disallowed characters (e.g.
+// "foo.bar" and "foo_bar") sanitize to the same label; use an
+// hclIdentSet to disambiguate those before emitting them.
+func hclIdent(name string) string {
+	ident := hclIdentDisallowed.ReplaceAllString(name, "_")
+	if ident == "" || (ident[0] >= '0' && ident[0] <= '9') {
+		ident = "_" + ident
+	}
+	return ident
+}
+
+// hclIdentSet hands out unique Terraform resource labels for a sequence
+// of names, suffixing a counter onto any name whose sanitized hclIdent
+// collides with one already handed out.
+type hclIdentSet struct {
+	seen map[string]int
+}
+
+func newHCLIdentSet() *hclIdentSet {
+	return &hclIdentSet{seen: make(map[string]int)}
+}
+
+// next returns a valid Terraform resource label for name, unique among
+// every name previously passed to this set.
+func (s *hclIdentSet) next(name string) string {
+	ident := hclIdent(name)
+	label := ident
+	for n := 2; s.seen[label] > 0; n++ {
+		label = fmt.Sprintf("%s_%d", ident, n)
+	}
+	s.seen[label]++
+	return label
+}
+
+// hclQuote renders s as a double-quoted HCL string literal.
+func hclQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
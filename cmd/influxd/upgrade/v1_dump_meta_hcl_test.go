@@ -0,0 +1,91 @@
+package upgrade
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxql"
+)
+
+func TestHCLIdent(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "simple", want: "simple"},
+		{name: "foo.bar", want: "foo_bar"},
+		{name: "foo bar/baz", want: "foo_bar_baz"},
+		{name: "123db", want: "_123db"},
+		{name: "", want: "_"},
+	}
+
+	for _, tt := range tests {
+		if got := hclIdent(tt.name); got != tt.want {
+			t.Errorf("hclIdent(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestHCLIdentSetDisambiguatesCollisions guards against two distinct
+// names that sanitize to the same hclIdent silently sharing one
+// Terraform resource label, which would produce invalid HCL with
+// duplicate resource addresses.
+func TestHCLIdentSetDisambiguatesCollisions(t *testing.T) {
+	s := newHCLIdentSet()
+
+	first := s.next("foo.bar")
+	second := s.next("foo_bar")
+	third := s.next("foo_bar")
+
+	if first == second || first == third || second == third {
+		t.Fatalf("expected three distinct labels, got %q, %q, %q", first, second, third)
+	}
+	if first != "foo_bar" {
+		t.Errorf("first occurrence should keep the plain sanitized ident, got %q", first)
+	}
+}
+
+func TestHCLIdentSetNoCollision(t *testing.T) {
+	s := newHCLIdentSet()
+
+	if got := s.next("telegraf"); got != "telegraf" {
+		t.Errorf("next(telegraf) = %q, want %q", got, "telegraf")
+	}
+	if got := s.next("metrics"); got != "metrics" {
+		t.Errorf("next(metrics) = %q, want %q", got, "metrics")
+	}
+}
+
+func TestHCLQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "plain", want: `"plain"`},
+		{in: `has "quotes"`, want: `"has \"quotes\""`},
+		{in: `back\slash`, want: `"back\\slash"`},
+	}
+
+	for _, tt := range tests {
+		if got := hclQuote(tt.in); got != tt.want {
+			t.Errorf("hclQuote(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHCLPrivilege(t *testing.T) {
+	tests := []struct {
+		priv influxql.Privilege
+		want string
+	}{
+		{priv: influxql.ReadPrivilege, want: "READ"},
+		{priv: influxql.WritePrivilege, want: "WRITE"},
+		{priv: influxql.AllPrivileges, want: "ALL"},
+		{priv: influxql.NoPrivileges, want: "NO PRIVILEGES"},
+	}
+
+	for _, tt := range tests {
+		if got := hclPrivilege(tt.priv); got != tt.want {
+			t.Errorf("hclPrivilege(%v) = %q, want %q", tt.priv, got, tt.want)
+		}
+	}
+}
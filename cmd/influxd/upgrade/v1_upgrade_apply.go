@@ -0,0 +1,356 @@
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/fluxinit"
+	"github.com/spf13/cobra"
+)
+
+var v1UpgradeApplyOptions = struct {
+	optionsV2
+	jsonIn string
+	dryRun bool
+}{}
+
+var v1UpgradeApplyCommand = &cobra.Command{
+	Use:    "v1-upgrade-apply",
+	Short:  "Provision a 2.x instance from a v1-dump-meta JSON export",
+	Args:   cobra.NoArgs,
+	Hidden: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fluxinit.FluxInit()
+
+		in, err := openV1DumpInput(v1UpgradeApplyOptions.jsonIn)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		var dump v1MetaDump
+		if err := json.NewDecoder(in).Decode(&dump); err != nil {
+			return fmt.Errorf("error reading v1 meta dump: %w", err)
+		}
+
+		svc, err := newInfluxDBv2(&v1UpgradeApplyOptions.optionsV2)
+		if err != nil {
+			return fmt.Errorf("error opening 2.x services: %w", err)
+		}
+
+		services := v1UpgradeServices{
+			Orgs:           svc.ts.OrganizationService,
+			Buckets:        svc.ts.BucketService,
+			DBRPs:          svc.dbrpSvc,
+			Users:          svc.ts.UserService,
+			Authorizations: svc.authSvc,
+			Passwords:      svc.passwordSvc,
+		}
+
+		return applyV1Dump(cmd.Context(), services, dump, v1UpgradeApplyOptions.dryRun, cmd.OutOrStdout())
+	},
+}
+
+func openV1DumpInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+func init() {
+	flags := v1UpgradeApplyCommand.Flags()
+	flags.StringVar(&v1UpgradeApplyOptions.jsonIn, "json-in", "v1meta.json", "Path to a v1-dump-meta --json export, or '-' to read from stdin")
+	flags.BoolVar(&v1UpgradeApplyOptions.dryRun, "dry-run", false, "Print the planned mutations instead of applying them")
+}
+
+// v1MetaDump is the decoded shape of the JSON produced by v1DumpMetaJSON.
+// Field names mirror that output exactly so a dump/apply pair can be
+// piped across hosts without an intermediate schema.
+type v1MetaDump struct {
+	DBRPs []v1DumpDBRP `json:"dbrps"`
+	Users []v1DumpUser `json:"users"`
+}
+
+type v1DumpDBRP struct {
+	DB              string `json:"db"`
+	RP              string `json:"rp"`
+	DurationSeconds int64  `json:"durationSeconds"`
+	Default         bool   `json:"default"`
+}
+
+type v1DumpUser struct {
+	Name     string   `json:"name"`
+	IsAdmin  bool     `json:"isAdmin"`
+	Hash     string   `json:"hash"`
+	ReadDBs  []string `json:"readDBs"`
+	WriteDBs []string `json:"writeDBs"`
+}
+
+// orgFinder is the subset of influxdb.OrganizationService applyV1Dump needs.
+type orgFinder interface {
+	FindOrganization(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error)
+	CreateOrganization(ctx context.Context, o *influxdb.Organization) error
+}
+
+// bucketFinder is the subset of influxdb.BucketService applyV1Dump needs.
+type bucketFinder interface {
+	FindBucket(ctx context.Context, filter influxdb.BucketFilter) (*influxdb.Bucket, error)
+	CreateBucket(ctx context.Context, b *influxdb.Bucket) error
+}
+
+// dbrpApplier is the subset of influxdb.DBRPMappingService applyV1Dump needs.
+type dbrpApplier interface {
+	FindMany(ctx context.Context, filter influxdb.DBRPMappingFilter) ([]*influxdb.DBRPMapping, int, error)
+	Create(ctx context.Context, m *influxdb.DBRPMapping) error
+}
+
+// userFinder is the subset of influxdb.UserService applyV1Dump needs.
+type userFinder interface {
+	FindUser(ctx context.Context, filter influxdb.UserFilter) (*influxdb.User, error)
+	CreateUser(ctx context.Context, u *influxdb.User) error
+}
+
+// authFinder is the subset of influxdb.AuthorizationService applyV1Dump needs.
+type authFinder interface {
+	FindAuthorizations(ctx context.Context, filter influxdb.AuthorizationFilter, opts ...influxdb.FindOptions) ([]*influxdb.Authorization, int, error)
+	CreateAuthorization(ctx context.Context, a *influxdb.Authorization) error
+}
+
+// passwordHashSetter is the subset of authorization.AuthedPasswordService applyV1Dump needs.
+type passwordHashSetter interface {
+	SetPasswordHash(ctx context.Context, authID influxdb.ID, hash string) error
+}
+
+// v1UpgradeServices bundles the 2.x services needed to provision
+// resources from a v1 meta dump.
+type v1UpgradeServices struct {
+	Orgs           orgFinder
+	Buckets        bucketFinder
+	DBRPs          dbrpApplier
+	Users          userFinder
+	Authorizations authFinder
+	Passwords      passwordHashSetter
+}
+
+// dbBucket is a bucket created for a v1 database, together with the org
+// it lives in, so that later permission grants can reference both IDs.
+type dbBucket struct {
+	BucketID influxdb.ID
+	OrgID    influxdb.ID
+}
+
+// applyV1Dump idempotently creates the 2.x resources equivalent to dump:
+// one org per v1 database, one bucket per (db, rp) with a matching
+// retention duration, a DBRP mapping for each, and users whose read/write
+// DB lists are translated into bucket-scoped read/write permissions.
+func applyV1Dump(ctx context.Context, svc v1UpgradeServices, dump v1MetaDump, dryRun bool, out io.Writer) error {
+	// bucketsByDB tracks every bucket created for a given v1 database, so
+	// that user privileges (granted per-database) can be expanded to the
+	// buckets that database's retention policies were mapped to.
+	bucketsByDB := map[string][]dbBucket{}
+
+	for _, dbrp := range dump.DBRPs {
+		org, err := findOrCreateOrg(ctx, svc.Orgs, dbrp.DB, dryRun, out)
+		if err != nil {
+			return fmt.Errorf("org for database %q: %w", dbrp.DB, err)
+		}
+
+		bucketName := fmt.Sprintf("%s/%s", dbrp.DB, dbrp.RP)
+		duration := time.Duration(dbrp.DurationSeconds) * time.Second
+		bucket, err := findOrCreateBucket(ctx, svc.Buckets, org, bucketName, duration, dryRun, out)
+		if err != nil {
+			return fmt.Errorf("bucket %q: %w", bucketName, err)
+		}
+		if bucket != nil {
+			bucketsByDB[dbrp.DB] = append(bucketsByDB[dbrp.DB], dbBucket{BucketID: bucket.ID, OrgID: org.ID})
+		}
+
+		if err := applyDBRPMapping(ctx, svc.DBRPs, dbrp, org, bucket, dryRun, out); err != nil {
+			return fmt.Errorf("dbrp mapping %q: %w", bucketName, err)
+		}
+	}
+
+	for _, user := range dump.Users {
+		if err := applyUser(ctx, svc, user, bucketsByDB, dryRun, out); err != nil {
+			return fmt.Errorf("user %q: %w", user.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func findOrCreateOrg(ctx context.Context, svc orgFinder, name string, dryRun bool, out io.Writer) (*influxdb.Organization, error) {
+	if org, err := svc.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &name}); err == nil {
+		return org, nil
+	}
+
+	if dryRun {
+		fmt.Fprintf(out, "would create org %q\n", name)
+		return &influxdb.Organization{Name: name}, nil
+	}
+
+	org := &influxdb.Organization{Name: name}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+func findOrCreateBucket(ctx context.Context, svc bucketFinder, org *influxdb.Organization, name string, duration time.Duration, dryRun bool, out io.Writer) (*influxdb.Bucket, error) {
+	if bucket, err := svc.FindBucket(ctx, influxdb.BucketFilter{Name: &name, OrganizationID: &org.ID}); err == nil {
+		return bucket, nil
+	}
+
+	if dryRun {
+		fmt.Fprintf(out, "would create bucket %q (retention %s) in org %q\n", name, duration, org.Name)
+		return &influxdb.Bucket{Name: name, OrgID: org.ID, RetentionPeriod: duration}, nil
+	}
+
+	bucket := &influxdb.Bucket{
+		OrgID:           org.ID,
+		Name:            name,
+		RetentionPeriod: duration,
+	}
+	if err := svc.CreateBucket(ctx, bucket); err != nil {
+		return nil, err
+	}
+	return bucket, nil
+}
+
+func applyDBRPMapping(ctx context.Context, svc dbrpApplier, dbrp v1DumpDBRP, org *influxdb.Organization, bucket *influxdb.Bucket, dryRun bool, out io.Writer) error {
+	if dryRun {
+		fmt.Fprintf(out, "would map db=%s rp=%s (default=%v) to bucket %q\n", dbrp.DB, dbrp.RP, dbrp.Default, bucket.Name)
+		return nil
+	}
+
+	mapping := &influxdb.DBRPMapping{
+		Database:        dbrp.DB,
+		RetentionPolicy: dbrp.RP,
+		Default:         dbrp.Default,
+		OrganizationID:  org.ID,
+		BucketID:        bucket.ID,
+	}
+	if existing, _, err := svc.FindMany(ctx, influxdb.DBRPMappingFilter{
+		OrgID:           &org.ID,
+		Database:        &dbrp.DB,
+		RetentionPolicy: &dbrp.RP,
+	}); err == nil && len(existing) > 0 {
+		return nil
+	}
+	return svc.Create(ctx, mapping)
+}
+
+func applyUser(ctx context.Context, svc v1UpgradeServices, user v1DumpUser, bucketsByDB map[string][]dbBucket, dryRun bool, out io.Writer) error {
+	u, err := svc.Users.FindUser(ctx, influxdb.UserFilter{Name: &user.Name})
+	if err != nil {
+		if dryRun {
+			fmt.Fprintf(out, "would create user %q\n", user.Name)
+			u = &influxdb.User{Name: user.Name}
+		} else {
+			u = &influxdb.User{Name: user.Name}
+			if err := svc.Users.CreateUser(ctx, u); err != nil {
+				return err
+			}
+		}
+	}
+
+	// An authorization is only ever created once per user: re-running
+	// against the same dump must not mint a new token every time.
+	existing, _, err := svc.Authorizations.FindAuthorizations(ctx, influxdb.AuthorizationFilter{UserID: &u.ID})
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		if len(existing) > 0 {
+			fmt.Fprintf(out, "%q already has an authorization, would restore its password hash only\n", user.Name)
+		} else if user.IsAdmin {
+			fmt.Fprintf(out, "would grant %q operator permissions (v1 admin) and restore its password hash\n", user.Name)
+		} else {
+			n := countBucketMatches(user.ReadDBs, user.WriteDBs, bucketsByDB)
+			fmt.Fprintf(out, "would grant %q %d permissions and restore its password hash\n", user.Name, n)
+		}
+		return nil
+	}
+
+	authID := influxdb.ID(0)
+	if len(existing) == 0 {
+		// A v1 admin's privileges are global, not expressed as per-database
+		// entries in ReadDBs/WriteDBs, so they translate to operator
+		// permissions rather than bucket-scoped ones.
+		var perms []influxdb.Permission
+		if user.IsAdmin {
+			perms = influxdb.OperPermissions()
+		} else {
+			var err error
+			perms, err = bucketPermissions(user.ReadDBs, user.WriteDBs, bucketsByDB)
+			if err != nil {
+				return err
+			}
+		}
+
+		auth := &influxdb.Authorization{
+			UserID:      u.ID,
+			Permissions: perms,
+			Status:      influxdb.Active,
+		}
+		if err := svc.Authorizations.CreateAuthorization(ctx, auth); err != nil {
+			return err
+		}
+		authID = auth.ID
+	} else {
+		authID = existing[0].ID
+	}
+
+	if user.Hash == "" {
+		return nil
+	}
+	return svc.Passwords.SetPasswordHash(ctx, authID, user.Hash)
+}
+
+// countBucketMatches reports how many bucket permissions readDBs and
+// writeDBs would expand to, without constructing any Permission values.
+// It is safe to call during --dry-run, when bucketsByDB entries carry
+// synthesized zero IDs that NewPermissionAtID would reject.
+func countBucketMatches(readDBs, writeDBs []string, bucketsByDB map[string][]dbBucket) int {
+	n := 0
+	for _, db := range readDBs {
+		n += len(bucketsByDB[db])
+	}
+	for _, db := range writeDBs {
+		n += len(bucketsByDB[db])
+	}
+	return n
+}
+
+// bucketPermissions expands a user's v1 per-database read/write lists
+// into bucket-scoped 2.x permissions, one pair per bucket that database
+// was mapped to.
+func bucketPermissions(readDBs, writeDBs []string, bucketsByDB map[string][]dbBucket) ([]influxdb.Permission, error) {
+	var perms []influxdb.Permission
+	add := func(dbs []string, action influxdb.Action) error {
+		for _, db := range dbs {
+			for _, b := range bucketsByDB[db] {
+				p, err := influxdb.NewPermissionAtID(b.BucketID, action, influxdb.BucketsResourceType, b.OrgID)
+				if err != nil {
+					return err
+				}
+				perms = append(perms, *p)
+			}
+		}
+		return nil
+	}
+	if err := add(readDBs, influxdb.ReadAction); err != nil {
+		return nil, err
+	}
+	if err := add(writeDBs, influxdb.WriteAction); err != nil {
+		return nil, err
+	}
+	return perms, nil
+}
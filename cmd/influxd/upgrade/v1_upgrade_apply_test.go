@@ -0,0 +1,276 @@
+package upgrade
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// fakeV1UpgradeServices is an in-memory stand-in for the 2.x services
+// applyV1Dump talks to, just enough to exercise its idempotency and
+// permission-building logic without a real store.
+type fakeV1UpgradeServices struct {
+	orgs   map[string]*influxdb.Organization
+	nextID influxdb.ID
+
+	buckets map[string]*influxdb.Bucket // keyed by "orgID/name"
+	dbrps   []*influxdb.DBRPMapping
+
+	users map[string]*influxdb.User
+	auths []*influxdb.Authorization
+
+	passwordHashes map[influxdb.ID]string
+}
+
+func newFakeV1UpgradeServices() *fakeV1UpgradeServices {
+	return &fakeV1UpgradeServices{
+		orgs:           map[string]*influxdb.Organization{},
+		buckets:        map[string]*influxdb.Bucket{},
+		users:          map[string]*influxdb.User{},
+		passwordHashes: map[influxdb.ID]string{},
+	}
+}
+
+func (f *fakeV1UpgradeServices) newID() influxdb.ID {
+	f.nextID++
+	return f.nextID
+}
+
+func (f *fakeV1UpgradeServices) FindOrganization(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+	if filter.Name != nil {
+		if org, ok := f.orgs[*filter.Name]; ok {
+			return org, nil
+		}
+	}
+	return nil, errors.New("organization not found")
+}
+
+func (f *fakeV1UpgradeServices) CreateOrganization(ctx context.Context, o *influxdb.Organization) error {
+	o.ID = f.newID()
+	f.orgs[o.Name] = o
+	return nil
+}
+
+func bucketKey(orgID influxdb.ID, name string) string {
+	return orgID.String() + "/" + name
+}
+
+func (f *fakeV1UpgradeServices) FindBucket(ctx context.Context, filter influxdb.BucketFilter) (*influxdb.Bucket, error) {
+	if filter.Name != nil && filter.OrganizationID != nil {
+		if b, ok := f.buckets[bucketKey(*filter.OrganizationID, *filter.Name)]; ok {
+			return b, nil
+		}
+	}
+	return nil, errors.New("bucket not found")
+}
+
+func (f *fakeV1UpgradeServices) CreateBucket(ctx context.Context, b *influxdb.Bucket) error {
+	b.ID = f.newID()
+	f.buckets[bucketKey(b.OrgID, b.Name)] = b
+	return nil
+}
+
+func (f *fakeV1UpgradeServices) FindMany(ctx context.Context, filter influxdb.DBRPMappingFilter) ([]*influxdb.DBRPMapping, int, error) {
+	var found []*influxdb.DBRPMapping
+	for _, m := range f.dbrps {
+		if filter.Database != nil && m.Database != *filter.Database {
+			continue
+		}
+		if filter.RetentionPolicy != nil && m.RetentionPolicy != *filter.RetentionPolicy {
+			continue
+		}
+		found = append(found, m)
+	}
+	return found, len(found), nil
+}
+
+func (f *fakeV1UpgradeServices) Create(ctx context.Context, m *influxdb.DBRPMapping) error {
+	f.dbrps = append(f.dbrps, m)
+	return nil
+}
+
+func (f *fakeV1UpgradeServices) FindUser(ctx context.Context, filter influxdb.UserFilter) (*influxdb.User, error) {
+	if filter.Name != nil {
+		if u, ok := f.users[*filter.Name]; ok {
+			return u, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func (f *fakeV1UpgradeServices) CreateUser(ctx context.Context, u *influxdb.User) error {
+	u.ID = f.newID()
+	f.users[u.Name] = u
+	return nil
+}
+
+func (f *fakeV1UpgradeServices) FindAuthorizations(ctx context.Context, filter influxdb.AuthorizationFilter, opts ...influxdb.FindOptions) ([]*influxdb.Authorization, int, error) {
+	var found []*influxdb.Authorization
+	for _, a := range f.auths {
+		if filter.UserID != nil && a.UserID == *filter.UserID {
+			found = append(found, a)
+		}
+	}
+	return found, len(found), nil
+}
+
+func (f *fakeV1UpgradeServices) CreateAuthorization(ctx context.Context, a *influxdb.Authorization) error {
+	a.ID = f.newID()
+	f.auths = append(f.auths, a)
+	return nil
+}
+
+func (f *fakeV1UpgradeServices) SetPasswordHash(ctx context.Context, authID influxdb.ID, hash string) error {
+	f.passwordHashes[authID] = hash
+	return nil
+}
+
+func (f *fakeV1UpgradeServices) asV1UpgradeServices() v1UpgradeServices {
+	return v1UpgradeServices{
+		Orgs:           f,
+		Buckets:        f,
+		DBRPs:          f,
+		Users:          f,
+		Authorizations: f,
+		Passwords:      f,
+	}
+}
+
+func testDump() v1MetaDump {
+	return v1MetaDump{
+		DBRPs: []v1DumpDBRP{
+			{DB: "telegraf", RP: "autogen", DurationSeconds: 0, Default: true},
+		},
+		Users: []v1DumpUser{
+			{Name: "admin", Hash: "hashed-password", ReadDBs: []string{"telegraf"}, WriteDBs: []string{"telegraf"}},
+		},
+	}
+}
+
+// TestApplyV1DumpUsesRealOrgID guards against regressing to a hardcoded
+// zero org ID when building bucket-scoped permissions: every permission
+// granted must reference the org the bucket actually lives in.
+func TestApplyV1DumpUsesRealOrgID(t *testing.T) {
+	f := newFakeV1UpgradeServices()
+	if err := applyV1Dump(context.Background(), f.asV1UpgradeServices(), testDump(), false, &bytes.Buffer{}); err != nil {
+		t.Fatalf("applyV1Dump: %v", err)
+	}
+
+	if len(f.auths) != 1 {
+		t.Fatalf("got %d authorizations, want 1", len(f.auths))
+	}
+	org, ok := f.orgs["telegraf"]
+	if !ok {
+		t.Fatal("expected an org named \"telegraf\" to have been created")
+	}
+	if len(f.auths[0].Permissions) == 0 {
+		t.Fatal("expected at least one permission to have been granted")
+	}
+	for _, p := range f.auths[0].Permissions {
+		if p.Resource.OrgID == nil || *p.Resource.OrgID != org.ID {
+			t.Errorf("permission org ID = %v, want %v", p.Resource.OrgID, org.ID)
+		}
+	}
+}
+
+// TestApplyV1DumpDryRunDoesNotError guards against --dry-run failing on
+// a dump with user privileges, which it previously did because it built
+// real Permission values against synthesized, zero-valued IDs.
+func TestApplyV1DumpDryRunDoesNotError(t *testing.T) {
+	f := newFakeV1UpgradeServices()
+	if err := applyV1Dump(context.Background(), f.asV1UpgradeServices(), testDump(), true, &bytes.Buffer{}); err != nil {
+		t.Fatalf("applyV1Dump(dryRun=true): %v", err)
+	}
+	if len(f.auths) != 0 {
+		t.Errorf("dry-run should not create any authorizations, got %d", len(f.auths))
+	}
+}
+
+// TestApplyV1DumpIsIdempotent guards against re-running apply against
+// the same dump minting a duplicate authorization/token per user.
+func TestApplyV1DumpIsIdempotent(t *testing.T) {
+	f := newFakeV1UpgradeServices()
+	dump := testDump()
+
+	if err := applyV1Dump(context.Background(), f.asV1UpgradeServices(), dump, false, &bytes.Buffer{}); err != nil {
+		t.Fatalf("first applyV1Dump: %v", err)
+	}
+	if err := applyV1Dump(context.Background(), f.asV1UpgradeServices(), dump, false, &bytes.Buffer{}); err != nil {
+		t.Fatalf("second applyV1Dump: %v", err)
+	}
+
+	if len(f.auths) != 1 {
+		t.Fatalf("got %d authorizations after running apply twice, want 1", len(f.auths))
+	}
+	if len(f.orgs) != 1 || len(f.buckets) != 1 || len(f.dbrps) != 1 {
+		t.Errorf("expected exactly one org/bucket/dbrp, got %d/%d/%d", len(f.orgs), len(f.buckets), len(f.dbrps))
+	}
+}
+
+// TestApplyV1DumpGrantsAdminOperatorPermissions guards against a v1
+// superuser (whose privileges are global, not per-database) ending up
+// with a zero-permission authorization, since admins typically have no
+// entries in ReadDBs/WriteDBs for bucketPermissions to expand.
+func TestApplyV1DumpGrantsAdminOperatorPermissions(t *testing.T) {
+	f := newFakeV1UpgradeServices()
+	dump := v1MetaDump{
+		Users: []v1DumpUser{
+			{Name: "root", IsAdmin: true, Hash: "hashed-password"},
+		},
+	}
+
+	if err := applyV1Dump(context.Background(), f.asV1UpgradeServices(), dump, false, &bytes.Buffer{}); err != nil {
+		t.Fatalf("applyV1Dump: %v", err)
+	}
+
+	if len(f.auths) != 1 {
+		t.Fatalf("got %d authorizations, want 1", len(f.auths))
+	}
+	if len(f.auths[0].Permissions) == 0 {
+		t.Fatal("admin user should have been granted operator permissions, got none")
+	}
+	want := influxdb.OperPermissions()
+	if len(f.auths[0].Permissions) != len(want) {
+		t.Errorf("got %d permissions, want %d (influxdb.OperPermissions())", len(f.auths[0].Permissions), len(want))
+	}
+}
+
+// TestApplyV1DumpDryRunAdminDoesNotError guards against --dry-run
+// failing (or miscounting) for an admin user with empty ReadDBs/WriteDBs.
+func TestApplyV1DumpDryRunAdminDoesNotError(t *testing.T) {
+	f := newFakeV1UpgradeServices()
+	dump := v1MetaDump{
+		Users: []v1DumpUser{
+			{Name: "root", IsAdmin: true, Hash: "hashed-password"},
+		},
+	}
+
+	if err := applyV1Dump(context.Background(), f.asV1UpgradeServices(), dump, true, &bytes.Buffer{}); err != nil {
+		t.Fatalf("applyV1Dump(dryRun=true): %v", err)
+	}
+}
+
+func TestBucketPermissionsUsesBucketAndOrgID(t *testing.T) {
+	bucketsByDB := map[string][]dbBucket{
+		"telegraf": {{BucketID: 10, OrgID: 20}},
+	}
+
+	perms, err := bucketPermissions([]string{"telegraf"}, []string{"telegraf"}, bucketsByDB)
+	if err != nil {
+		t.Fatalf("bucketPermissions: %v", err)
+	}
+	if len(perms) != 2 {
+		t.Fatalf("got %d permissions, want 2 (one read, one write)", len(perms))
+	}
+	for _, p := range perms {
+		if p.Resource.ID == nil || *p.Resource.ID != influxdb.ID(10) {
+			t.Errorf("permission bucket ID = %v, want 10", p.Resource.ID)
+		}
+		if p.Resource.OrgID == nil || *p.Resource.OrgID != influxdb.ID(20) {
+			t.Errorf("permission org ID = %v, want 20", p.Resource.OrgID)
+		}
+	}
+}
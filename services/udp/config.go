@@ -0,0 +1,146 @@
+package udp
+
+import (
+	"errors"
+	"time"
+
+	"github.com/influxdata/influxdb/toml"
+)
+
+const (
+	// DefaultBindAddress is the default binding interface if none is specified.
+	DefaultBindAddress = ":8089"
+
+	// DefaultDatabase is the default database if none is specified.
+	DefaultDatabase = "udp"
+
+	// DefaultBatchSize is the default UDP batch size.
+	DefaultBatchSize = 5000
+
+	// DefaultBatchPending is the default number of pending UDP batches.
+	DefaultBatchPending = 10
+
+	// DefaultBatchTimeout is the default UDP batch timeout.
+	DefaultBatchTimeout = toml.Duration(time.Second)
+
+	// DefaultReadBuffer is the default UDP read buffer size.
+	DefaultReadBuffer = 0
+
+	// DefaultPrecision is the default timestamp precision used when parsing line protocol.
+	DefaultPrecision = "n"
+
+	// DefaultParserType is the default parser used for incoming datagrams.
+	DefaultParserType = ParserTypeLineProtocol
+
+	// DefaultSeparator is the default character used to join unmatched
+	// graphite template fields into a measurement name.
+	DefaultSeparator = "."
+)
+
+// ParserType identifies the datagram format a udp.Service should expect.
+type ParserType string
+
+const (
+	// ParserTypeLineProtocol parses datagrams as InfluxDB line protocol.
+	ParserTypeLineProtocol ParserType = "line-protocol"
+
+	// ParserTypeGraphite parses datagrams as Graphite plaintext protocol,
+	// using the configured templates to derive a measurement and tags.
+	ParserTypeGraphite ParserType = "graphite"
+
+	// ParserTypeJSON parses datagrams as JSON objects.
+	ParserTypeJSON ParserType = "json"
+)
+
+// Config holds various configuration settings for the UDP listener.
+type Config struct {
+	Enabled         bool          `toml:"enabled"`
+	BindAddress     string        `toml:"bind-address"`
+	Database        string        `toml:"database"`
+	RetentionPolicy string        `toml:"retention-policy"`
+	BatchSize       int           `toml:"batch-size"`
+	BatchPending    int           `toml:"batch-pending"`
+	BatchTimeout    toml.Duration `toml:"batch-timeout"`
+	ReadBuffer      int           `toml:"read-buffer"`
+	Precision       string        `toml:"precision"`
+
+	// Parser selects how incoming datagrams are decoded into points.
+	// One of "line-protocol" (default), "graphite" or "json".
+	Parser ParserType `toml:"parser"`
+
+	// Separator joins the unmatched trailing fields of a graphite template
+	// into a measurement name when a "measurement*" field is present.
+	Separator string `toml:"separator"`
+
+	// Templates are graphite template patterns, evaluated in the order
+	// given with the longest matching filter winning, e.g.
+	// "servers.* .host.measurement*". A template with no filter (no
+	// leading space-delimited prefix) is used as the default template
+	// for metrics that no other template matches.
+	Templates []string `toml:"templates"`
+
+	// Subscriptions are HTTP(S) destinations that receive a live copy of
+	// every batch written locally by the UDP service.
+	Subscriptions []SubscriptionConfig `toml:"subscriptions"`
+}
+
+// NewConfig returns a new Config with defaults.
+func NewConfig() Config {
+	return Config{
+		BindAddress:  DefaultBindAddress,
+		Database:     DefaultDatabase,
+		BatchSize:    DefaultBatchSize,
+		BatchPending: DefaultBatchPending,
+		BatchTimeout: DefaultBatchTimeout,
+		ReadBuffer:   DefaultReadBuffer,
+		Precision:    DefaultPrecision,
+		Parser:       DefaultParserType,
+		Separator:    DefaultSeparator,
+	}
+}
+
+// WithDefaults takes the given config and returns a new config with any
+// required default values set.
+func (c *Config) WithDefaults() *Config {
+	d := *c
+	if d.BindAddress == "" {
+		d.BindAddress = DefaultBindAddress
+	}
+	if d.Database == "" {
+		d.Database = DefaultDatabase
+	}
+	if d.BatchSize == 0 {
+		d.BatchSize = DefaultBatchSize
+	}
+	if d.BatchPending == 0 {
+		d.BatchPending = DefaultBatchPending
+	}
+	if d.BatchTimeout == 0 {
+		d.BatchTimeout = DefaultBatchTimeout
+	}
+	if d.Precision == "" {
+		d.Precision = DefaultPrecision
+	}
+	if d.Parser == "" {
+		d.Parser = DefaultParserType
+	}
+	if d.Separator == "" {
+		d.Separator = DefaultSeparator
+	}
+	return &d
+}
+
+// Validate returns an error if the config is invalid.
+func (c *Config) Validate() error {
+	switch c.Parser {
+	case "", ParserTypeLineProtocol, ParserTypeGraphite, ParserTypeJSON:
+	default:
+		return errors.New("parser must be one of 'line-protocol', 'graphite' or 'json'")
+	}
+	if c.Parser == ParserTypeGraphite {
+		if _, err := NewGraphiteParser(c.Separator, c.Templates); err != nil {
+			return err
+		}
+	}
+	return nil
+}
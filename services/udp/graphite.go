@@ -0,0 +1,234 @@
+package udp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// measurementField is the template field name that captures the
+// measurement name. A trailing '*' makes it greedy, joining every
+// remaining bucket of the metric path with the parser's separator.
+const measurementField = "measurement"
+
+// GraphiteParser parses the Graphite plaintext protocol
+// ("<path> <value> <timestamp>\n") into points, deriving a measurement
+// name and tags from the dot-separated metric path according to a set
+// of user supplied templates.
+type GraphiteParser struct {
+	separator       string
+	templates       []*graphiteTemplate
+	defaultTemplate *graphiteTemplate
+}
+
+// NewGraphiteParser builds a GraphiteParser from the given separator and
+// template patterns. Templates are a string of the form
+// "<filter> <template> [tags]", e.g. "servers.* .host.measurement*
+// region=us-west". A template with no filter component is used as the
+// default template for paths that no filtered template matches.
+func NewGraphiteParser(separator string, templates []string) (*GraphiteParser, error) {
+	if separator == "" {
+		separator = DefaultSeparator
+	}
+
+	p := &GraphiteParser{separator: separator}
+	for _, pattern := range templates {
+		tmpl, err := parseGraphiteTemplate(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(tmpl.filter) == 0 {
+			if p.defaultTemplate != nil {
+				return nil, fmt.Errorf("only one default template is allowed, found more than one in %q", pattern)
+			}
+			p.defaultTemplate = tmpl
+			continue
+		}
+		p.templates = append(p.templates, tmpl)
+	}
+
+	// Longest (most specific) filter wins; ties keep the order the
+	// templates were given in.
+	sort.SliceStable(p.templates, func(i, j int) bool {
+		return len(p.templates[i].filter) > len(p.templates[j].filter)
+	})
+
+	return p, nil
+}
+
+// Parse implements PointParser.
+func (p *GraphiteParser) Parse(buf []byte, defaultTime time.Time, precision string) (models.Points, error) {
+	var points models.Points
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		point, err := p.parseLine(line, defaultTime)
+		if err != nil {
+			return points, err
+		}
+		points = append(points, point)
+	}
+	return points, scanner.Err()
+}
+
+func (p *GraphiteParser) parseLine(line string, defaultTime time.Time) (models.Point, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 2 && len(fields) != 3 {
+		return nil, fmt.Errorf("received %q which doesn't have a metric path, value and optional timestamp", line)
+	}
+
+	path, rawValue := fields[0], fields[1]
+	buckets := strings.Split(path, ".")
+
+	tmpl := p.match(buckets)
+	if tmpl == nil {
+		return nil, fmt.Errorf("no template matched for %q", path)
+	}
+	name, tags, err := tmpl.apply(buckets, p.separator)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := strconv.ParseFloat(rawValue, 64)
+	if err != nil {
+		return nil, fmt.Errorf("field %q value %q: %w", path, rawValue, err)
+	}
+
+	ts := defaultTime
+	if len(fields) == 3 {
+		unixTime, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("field %q timestamp %q: %w", path, fields[2], err)
+		}
+		ts = time.Unix(0, int64(unixTime*float64(time.Second))).UTC()
+	}
+
+	return models.NewPoint(name, models.NewTags(tags), models.Fields{"value": value}, ts)
+}
+
+// match returns the most specific template whose filter matches buckets,
+// falling back to the default template if none do.
+func (p *GraphiteParser) match(buckets []string) *graphiteTemplate {
+	for _, tmpl := range p.templates {
+		if tmpl.matches(buckets) {
+			return tmpl
+		}
+	}
+	return p.defaultTemplate
+}
+
+// graphiteTemplate is a single parsed "filter template [tags]" pattern.
+type graphiteTemplate struct {
+	filter []string
+	fields []string // parsed from the template, aligned to path buckets
+	tags   map[string]string
+}
+
+func parseGraphiteTemplate(pattern string) (*graphiteTemplate, error) {
+	parts := strings.Fields(pattern)
+	if len(parts) < 1 || len(parts) > 3 {
+		return nil, fmt.Errorf("invalid template %q", pattern)
+	}
+
+	tmpl := &graphiteTemplate{tags: map[string]string{}}
+
+	// A pattern with a single part is a bare template with no filter.
+	fieldSpec := parts[0]
+	if len(parts) > 1 {
+		tmpl.filter = strings.Split(parts[0], ".")
+		fieldSpec = parts[1]
+	}
+	tmpl.fields = strings.Split(fieldSpec, ".")
+
+	if len(parts) == 3 {
+		for _, kv := range strings.Split(parts[2], ",") {
+			pair := strings.SplitN(kv, "=", 2)
+			if len(pair) != 2 {
+				return nil, fmt.Errorf("invalid template tags %q", parts[2])
+			}
+			tmpl.tags[pair[0]] = pair[1]
+		}
+	}
+
+	return tmpl, nil
+}
+
+// matches reports whether the template's filter is a prefix match for
+// buckets, with "*" matching any single bucket.
+func (t *graphiteTemplate) matches(buckets []string) bool {
+	if len(t.filter) > len(buckets) {
+		return false
+	}
+	for i, f := range t.filter {
+		if f != "*" && f != buckets[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// apply maps buckets onto the template's fields, producing a
+// measurement name and a set of tags.
+func (t *graphiteTemplate) apply(buckets []string, separator string) (string, map[string]string, error) {
+	tags := make(map[string]string, len(t.tags))
+	for k, v := range t.tags {
+		tags[k] = v
+	}
+
+	var measurement []string
+	consumed := 0
+	for i, field := range t.fields {
+		if i >= len(buckets) {
+			break
+		}
+		consumed = i + 1
+
+		greedy := strings.HasSuffix(field, "*")
+		name := strings.TrimSuffix(field, "*")
+
+		switch name {
+		case "":
+			// Unnamed field; skip this bucket.
+		case measurementField:
+			if greedy {
+				measurement = append(measurement, buckets[i:]...)
+			} else {
+				measurement = append(measurement, buckets[i])
+			}
+		default:
+			if greedy {
+				tags[name] = strings.Join(buckets[i:], separator)
+			} else {
+				tags[name] = buckets[i]
+			}
+		}
+
+		if greedy {
+			consumed = len(buckets)
+			break
+		}
+	}
+
+	// Without a trailing greedy field, a template shorter than the path
+	// would otherwise silently drop the extra buckets instead of either
+	// folding them into the measurement or reporting a parse failure.
+	if consumed < len(buckets) {
+		return "", nil, fmt.Errorf("template %v has no greedy field to absorb the extra bucket(s) in %q",
+			t.fields, strings.Join(buckets, "."))
+	}
+
+	if len(measurement) == 0 {
+		return "", nil, fmt.Errorf("no measurement found for %q", strings.Join(buckets, "."))
+	}
+
+	return strings.Join(measurement, separator), tags, nil
+}
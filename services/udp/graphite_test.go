@@ -0,0 +1,157 @@
+package udp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGraphiteTemplateMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		buckets []string
+		want    bool
+	}{
+		{name: "exact prefix", filter: "servers.web01", buckets: []string{"servers", "web01", "cpu"}, want: true},
+		{name: "wildcard component", filter: "servers.*", buckets: []string{"servers", "web01", "cpu"}, want: true},
+		{name: "mismatched literal", filter: "servers.db01", buckets: []string{"servers", "web01", "cpu"}, want: false},
+		{name: "filter longer than path", filter: "servers.web01.cpu.load", buckets: []string{"servers", "web01"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parseGraphiteTemplate(tt.filter + " measurement*")
+			if err != nil {
+				t.Fatalf("parseGraphiteTemplate: %v", err)
+			}
+			if got := tmpl.matches(tt.buckets); got != tt.want {
+				t.Errorf("matches(%v) = %v, want %v", tt.buckets, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraphiteTemplateApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		buckets  []string
+		wantName string
+		wantTags map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "greedy measurement absorbs the remainder",
+			pattern:  "servers.* .host.measurement*",
+			buckets:  []string{"servers", "web01", "cpu", "load"},
+			wantName: "cpu.load",
+			wantTags: map[string]string{"host": "web01"},
+		},
+		{
+			name:     "greedy tag without a measurement field errors",
+			pattern:  "servers.* .host.region*",
+			buckets:  []string{"servers", "web01", "us", "west"},
+			wantName: "",
+			wantErr:  true, // no measurement field present
+		},
+		{
+			name:     "static tags from the template are preserved",
+			pattern:  "servers.* .host.measurement region=us-west",
+			buckets:  []string{"servers", "web01", "cpu"},
+			wantName: "cpu",
+			wantTags: map[string]string{"host": "web01", "region": "us-west"},
+		},
+		{
+			name:    "non-greedy template shorter than the path errors instead of truncating",
+			pattern: "host.measurement",
+			buckets: []string{"web01", "cpu", "load", "idle"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parseGraphiteTemplate(tt.pattern)
+			if err != nil {
+				t.Fatalf("parseGraphiteTemplate: %v", err)
+			}
+
+			name, tags, err := tmpl.apply(tt.buckets, ".")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("apply(%v) = %q, nil; want error", tt.buckets, name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("apply(%v): unexpected error: %v", tt.buckets, err)
+			}
+			if name != tt.wantName {
+				t.Errorf("apply(%v) name = %q, want %q", tt.buckets, name, tt.wantName)
+			}
+			for k, v := range tt.wantTags {
+				if tags[k] != v {
+					t.Errorf("apply(%v) tags[%q] = %q, want %q", tt.buckets, k, tags[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestNewGraphiteParserLongestPrefixWins(t *testing.T) {
+	p, err := NewGraphiteParser(".", []string{
+		"servers.* .host.measurement*",
+		"servers.web01.* .host.instance.measurement*",
+	})
+	if err != nil {
+		t.Fatalf("NewGraphiteParser: %v", err)
+	}
+
+	buckets := []string{"servers", "web01", "cpu", "load"}
+	tmpl := p.match(buckets)
+	name, tags, err := tmpl.apply(buckets, ".")
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	// The more specific "servers.web01.*" filter should win over the
+	// broader "servers.*" filter, regardless of template order.
+	if tags["instance"] != "cpu" {
+		t.Errorf("tags[instance] = %q, want %q (longest filter should have matched)", tags["instance"], "cpu")
+	}
+	if name != "load" {
+		t.Errorf("name = %q, want %q", name, "load")
+	}
+}
+
+func TestGraphiteParserParse(t *testing.T) {
+	p, err := NewGraphiteParser(".", []string{"servers.* .host.measurement*"})
+	if err != nil {
+		t.Fatalf("NewGraphiteParser: %v", err)
+	}
+
+	points, err := p.Parse([]byte("servers.web01.cpu.load 42 1500000000\n"), time.Now(), "n")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(points) != 1 {
+		t.Fatalf("got %d points, want 1", len(points))
+	}
+
+	line := points[0].String()
+	if !strings.HasPrefix(line, "cpu.load,host=web01 ") {
+		t.Errorf("got line %q, want it to start with %q", line, "cpu.load,host=web01 ")
+	}
+}
+
+func TestGraphiteParserParseTruncationError(t *testing.T) {
+	p, err := NewGraphiteParser(".", []string{"host.measurement"})
+	if err != nil {
+		t.Fatalf("NewGraphiteParser: %v", err)
+	}
+
+	if _, err := p.Parse([]byte("web01.cpu.load.idle 42 1500000000\n"), time.Now(), "n"); err == nil {
+		t.Fatal("Parse: expected an error for a path longer than the non-greedy template, got nil")
+	}
+}
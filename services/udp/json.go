@@ -0,0 +1,64 @@
+package udp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// jsonPoint is the wire shape accepted by the JSON parser: either a
+// single object or a JSON array of objects of this form.
+type jsonPoint struct {
+	Name   string                 `json:"name"`
+	Tags   map[string]string      `json:"tags"`
+	Fields map[string]interface{} `json:"fields"`
+	Time   *int64                 `json:"time"` // unix nanoseconds; defaults to now
+}
+
+// jsonParser decodes datagrams containing one JSON object, or a JSON
+// array of objects, each describing a single point.
+type jsonParser struct{}
+
+func (p *jsonParser) Parse(buf []byte, defaultTime time.Time, precision string) (models.Points, error) {
+	var raw json.RawMessage
+	if err := json.Unmarshal(buf, &raw); err != nil {
+		return nil, fmt.Errorf("invalid json: %w", err)
+	}
+
+	var jsonPoints []jsonPoint
+	if raw[0] == '[' {
+		if err := json.Unmarshal(raw, &jsonPoints); err != nil {
+			return nil, fmt.Errorf("invalid json: %w", err)
+		}
+	} else {
+		var jp jsonPoint
+		if err := json.Unmarshal(raw, &jp); err != nil {
+			return nil, fmt.Errorf("invalid json: %w", err)
+		}
+		jsonPoints = []jsonPoint{jp}
+	}
+
+	points := make(models.Points, 0, len(jsonPoints))
+	for _, jp := range jsonPoints {
+		if jp.Name == "" {
+			return points, fmt.Errorf("json point is missing required field \"name\"")
+		}
+		if len(jp.Fields) == 0 {
+			return points, fmt.Errorf("json point %q is missing required field \"fields\"", jp.Name)
+		}
+
+		ts := defaultTime
+		if jp.Time != nil {
+			ts = time.Unix(0, *jp.Time).UTC()
+		}
+
+		point, err := models.NewPoint(jp.Name, models.NewTags(jp.Tags), models.Fields(jp.Fields), ts)
+		if err != nil {
+			return points, err
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
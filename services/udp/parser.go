@@ -0,0 +1,43 @@
+package udp
+
+import (
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// PointParser parses a single received UDP datagram into points. Line
+// protocol, Graphite and JSON variants are provided so that the UDP
+// service can ingest metrics emitted by agents that don't speak line
+// protocol natively.
+type PointParser interface {
+	Parse(buf []byte, defaultTime time.Time, precision string) (models.Points, error)
+}
+
+// NewParser returns the PointParser configured by c.
+func NewParser(c Config) (PointParser, error) {
+	switch c.Parser {
+	case ParserTypeGraphite:
+		return NewGraphiteParser(c.Separator, c.Templates)
+	case ParserTypeJSON:
+		return &jsonParser{}, nil
+	case ParserTypeLineProtocol, "":
+		return &lineProtocolParser{}, nil
+	default:
+		return nil, errNoParser(c.Parser)
+	}
+}
+
+type errNoParser ParserType
+
+func (e errNoParser) Error() string {
+	return "unknown udp parser: " + string(e)
+}
+
+// lineProtocolParser parses datagrams as InfluxDB line protocol. This is
+// the historical, default behavior of the UDP service.
+type lineProtocolParser struct{}
+
+func (p *lineProtocolParser) Parse(buf []byte, defaultTime time.Time, precision string) (models.Points, error) {
+	return models.ParsePointsWithPrecision(buf, defaultTime, precision)
+}
@@ -27,13 +27,15 @@ const (
 
 // statistics gathered by the UDP package.
 const (
-	statPointsReceived      = "pointsRx"
-	statBytesReceived       = "bytesRx"
-	statPointsParseFail     = "pointsParseFail"
-	statReadFail            = "readFail"
-	statBatchesTransmitted  = "batchesTx"
-	statPointsTransmitted   = "pointsTx"
-	statBatchesTransmitFail = "batchesTxFail"
+	statPointsReceived        = "pointsRx"
+	statBytesReceived         = "bytesRx"
+	statPointsParseFail       = "pointsParseFail"
+	statReadFail              = "readFail"
+	statBatchesTransmitted    = "batchesTx"
+	statPointsTransmitted     = "pointsTx"
+	statBatchesTransmitFail   = "batchesTxFail"
+	statSubscriptionWriteFail = "subscriptionWriteFail"
+	statSubscriptionPointsTx  = "subscriptionPointsTx"
 )
 
 // Service is a UDP service that will listen for incoming packets of line protocol.
@@ -45,9 +47,11 @@ type Service struct {
 	mu    sync.RWMutex
 	ready bool // Has the required database been created?
 
-	parserChan chan []byte
-	batcher    *tsdb.PointBatcher
-	config     Config
+	parserChan  chan []byte
+	batcher     *tsdb.PointBatcher
+	config      Config
+	pointParser PointParser
+	subscriber  *Subscriber
 
 	PointsWriter interface {
 		WritePointsPrivileged(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error
@@ -107,6 +111,22 @@ func (s *Service) RunWithReady(ctx context.Context, ready chan struct{}, reg ser
 		return errors.New("database has to be specified in config")
 	}
 
+	s.pointParser, err = NewParser(s.config)
+	if err != nil {
+		s.Logger.Info("Failed to create point parser", zap.Error(err))
+		return err
+	}
+
+	s.subscriber, err = NewSubscriber(s.config.Subscriptions, s.stats, s.Logger)
+	if err != nil {
+		s.Logger.Info("Failed to create subscriber", zap.Error(err))
+		return err
+	}
+	if s.subscriber != nil {
+		s.subscriber.Open()
+		defer s.subscriber.Close()
+	}
+
 	s.addr, err = net.ResolveUDPAddr("udp", s.config.BindAddress)
 	if err != nil {
 		s.Logger.Info("Failed to resolve UDP address",
@@ -148,13 +168,15 @@ func (s *Service) RunWithReady(ctx context.Context, ready chan struct{}, reg ser
 
 // Statistics maintains statistics for the UDP service.
 type Statistics struct {
-	PointsReceived      int64
-	BytesReceived       int64
-	PointsParseFail     int64
-	ReadFail            int64
-	BatchesTransmitted  int64
-	PointsTransmitted   int64
-	BatchesTransmitFail int64
+	PointsReceived        int64
+	BytesReceived         int64
+	PointsParseFail       int64
+	ReadFail              int64
+	BatchesTransmitted    int64
+	PointsTransmitted     int64
+	BatchesTransmitFail   int64
+	SubscriptionWriteFail int64
+	SubscriptionPointsTx  int64
 }
 
 // Statistics returns statistics for periodic monitoring.
@@ -163,13 +185,15 @@ func (s *Service) Statistics(tags map[string]string) []models.Statistic {
 		Name: "udp",
 		Tags: s.defaultTags.Merge(tags),
 		Values: map[string]interface{}{
-			statPointsReceived:      atomic.LoadInt64(&s.stats.PointsReceived),
-			statBytesReceived:       atomic.LoadInt64(&s.stats.BytesReceived),
-			statPointsParseFail:     atomic.LoadInt64(&s.stats.PointsParseFail),
-			statReadFail:            atomic.LoadInt64(&s.stats.ReadFail),
-			statBatchesTransmitted:  atomic.LoadInt64(&s.stats.BatchesTransmitted),
-			statPointsTransmitted:   atomic.LoadInt64(&s.stats.PointsTransmitted),
-			statBatchesTransmitFail: atomic.LoadInt64(&s.stats.BatchesTransmitFail),
+			statPointsReceived:        atomic.LoadInt64(&s.stats.PointsReceived),
+			statBytesReceived:         atomic.LoadInt64(&s.stats.BytesReceived),
+			statPointsParseFail:       atomic.LoadInt64(&s.stats.PointsParseFail),
+			statReadFail:              atomic.LoadInt64(&s.stats.ReadFail),
+			statBatchesTransmitted:    atomic.LoadInt64(&s.stats.BatchesTransmitted),
+			statPointsTransmitted:     atomic.LoadInt64(&s.stats.PointsTransmitted),
+			statBatchesTransmitFail:   atomic.LoadInt64(&s.stats.BatchesTransmitFail),
+			statSubscriptionWriteFail: atomic.LoadInt64(&s.stats.SubscriptionWriteFail),
+			statSubscriptionPointsTx:  atomic.LoadInt64(&s.stats.SubscriptionPointsTx),
 		},
 	}}
 }
@@ -182,6 +206,10 @@ func (s *Service) writer(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case batch := <-s.batcher.Out():
+			// Fork a copy to any configured subscribers; this never
+			// blocks or otherwise affects the local write below.
+			s.subscriber.Send(batch)
+
 			// Will attempt to create database if not yet created.
 			if err := s.createInternalStorage(); err != nil {
 				s.Logger.Info("Required database does not yet exist",
@@ -250,7 +278,7 @@ func (s *Service) parser(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case buf := <-s.parserChan:
-			points, err := models.ParsePointsWithPrecision(buf, time.Now().UTC(), s.config.Precision)
+			points, err := s.pointParser.Parse(buf, time.Now().UTC(), s.config.Precision)
 			if err != nil {
 				atomic.AddInt64(&s.stats.PointsParseFail, 1)
 				s.Logger.Info("Failed to parse points", zap.Error(err))
@@ -293,4 +321,4 @@ func (s *Service) WithLogger(log *zap.Logger) {
 // Addr returns the listener's address.
 func (s *Service) Addr() net.Addr {
 	return s.addr
-}
\ No newline at end of file
+}
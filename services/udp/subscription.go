@@ -0,0 +1,236 @@
+package udp
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/toml"
+	"go.uber.org/zap"
+)
+
+const (
+	// subscriberChanLen is the size of the buffered channel batches are
+	// forwarded through. Arbitrary, but large enough to absorb a slow
+	// subscriber for a short while before batches start getting dropped.
+	subscriberChanLen = 100
+)
+
+// SubscriptionMode controls how a subscription with multiple
+// destinations is written to.
+type SubscriptionMode string
+
+const (
+	// SubscriptionModeAll writes every batch to every destination.
+	SubscriptionModeAll SubscriptionMode = "ALL"
+
+	// SubscriptionModeAny writes each batch to a single destination,
+	// chosen by round-robin load balancing across all destinations.
+	SubscriptionModeAny SubscriptionMode = "ANY"
+)
+
+// SubscriptionConfig describes a single HTTP(S) subscriber that should
+// receive a live copy of every batch the UDP service writes locally.
+type SubscriptionConfig struct {
+	Name         string           `toml:"name"`
+	Protocol     string           `toml:"protocol"` // "http" or "https"
+	Mode         SubscriptionMode `toml:"mode"`
+	Destinations []string         `toml:"destinations"`
+	Timeout      toml.Duration    `toml:"timeout"`
+	Username     string           `toml:"username"`
+	Password     string           `toml:"password"`
+	BearerToken  string           `toml:"bearer-token"`
+}
+
+// Subscriber forwards batches written by the UDP service to one or more
+// HTTP(S) destinations, without blocking or slowing down the primary
+// write path. Each destination has its own queue and delivery goroutine,
+// so a batch that cannot be queued because one destination is falling
+// behind is dropped and counted for that destination only, and a slow
+// or unreachable destination cannot stall delivery to the others.
+type Subscriber struct {
+	closing chan struct{}
+	wg      sync.WaitGroup
+
+	destinations []*subscriptionDestination
+
+	stats  *Statistics
+	Logger *zap.Logger
+}
+
+// NewSubscriber builds a Subscriber from the given subscription configs.
+// It returns nil, nil if no subscriptions are configured.
+func NewSubscriber(configs []SubscriptionConfig, stats *Statistics, logger *zap.Logger) (*Subscriber, error) {
+	if len(configs) == 0 {
+		return nil, nil
+	}
+
+	s := &Subscriber{
+		closing: make(chan struct{}),
+		stats:   stats,
+		Logger:  logger,
+	}
+
+	for _, c := range configs {
+		dest, err := newSubscriptionDestination(c)
+		if err != nil {
+			return nil, err
+		}
+		s.destinations = append(s.destinations, dest)
+	}
+
+	return s, nil
+}
+
+// Open starts forwarding batches in the background, one goroutine per
+// destination.
+func (s *Subscriber) Open() {
+	for _, dest := range s.destinations {
+		s.wg.Add(1)
+		go s.run(dest)
+	}
+}
+
+// Close stops forwarding batches, waiting for any in-flight write to finish.
+func (s *Subscriber) Close() {
+	close(s.closing)
+	s.wg.Wait()
+}
+
+// Send forwards batch to every configured subscription. It never blocks
+// the caller: if a subscription's queue is full, the batch is dropped
+// for that subscription and a failure is recorded.
+func (s *Subscriber) Send(batch models.Points) {
+	if s == nil {
+		return
+	}
+	for _, dest := range s.destinations {
+		dest.enqueue(batch, s.stats, s.Logger)
+	}
+}
+
+func (s *Subscriber) run(dest *subscriptionDestination) {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.closing:
+			return
+		case batch := <-dest.batches:
+			dest.write(batch, s.stats, s.Logger)
+		}
+	}
+}
+
+// subscriptionDestination is a single HTTP(S) subscriber, potentially
+// backed by several load-balanced URLs.
+type subscriptionDestination struct {
+	name    string
+	mode    SubscriptionMode
+	urls    []string
+	next    uint32 // round-robin cursor for SubscriptionModeAny
+	client  *http.Client
+	batches chan models.Points
+
+	username    string
+	password    string
+	bearerToken string
+}
+
+func newSubscriptionDestination(c SubscriptionConfig) (*subscriptionDestination, error) {
+	if len(c.Destinations) == 0 {
+		return nil, fmt.Errorf("subscription %q: at least one destination is required", c.Name)
+	}
+	switch c.Protocol {
+	case "http", "https":
+	default:
+		return nil, fmt.Errorf("subscription %q: protocol must be 'http' or 'https'", c.Name)
+	}
+
+	mode := c.Mode
+	if mode == "" {
+		mode = SubscriptionModeAll
+	}
+	if mode != SubscriptionModeAll && mode != SubscriptionModeAny {
+		return nil, fmt.Errorf("subscription %q: mode must be 'ALL' or 'ANY'", c.Name)
+	}
+
+	timeout := time.Duration(c.Timeout)
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &subscriptionDestination{
+		name:        c.Name,
+		mode:        mode,
+		urls:        c.Destinations,
+		client:      &http.Client{Timeout: timeout},
+		batches:     make(chan models.Points, subscriberChanLen),
+		username:    c.Username,
+		password:    c.Password,
+		bearerToken: c.BearerToken,
+	}, nil
+}
+
+// enqueue queues batch for delivery to d without blocking the caller: if
+// d's queue is full, the batch is dropped for d only and a failure is
+// recorded, leaving other destinations unaffected.
+func (d *subscriptionDestination) enqueue(batch models.Points, stats *Statistics, logger *zap.Logger) {
+	select {
+	case d.batches <- batch:
+	default:
+		atomic.AddInt64(&stats.SubscriptionWriteFail, 1)
+		logger.Info("Dropped batch, subscriber queue full", zap.String("subscription", d.name))
+	}
+}
+
+func (d *subscriptionDestination) write(batch models.Points, stats *Statistics, logger *zap.Logger) {
+	urls := d.urls
+	if d.mode == SubscriptionModeAny {
+		i := atomic.AddUint32(&d.next, 1) - 1
+		urls = []string{d.urls[int(i)%len(d.urls)]}
+	}
+
+	for _, url := range urls {
+		if err := d.post(url, batch); err != nil {
+			atomic.AddInt64(&stats.SubscriptionWriteFail, 1)
+			logger.Info("Failed to forward batch to subscription",
+				zap.String("subscription", d.name), zap.String("url", url), zap.Error(err))
+			continue
+		}
+		atomic.AddInt64(&stats.SubscriptionPointsTx, int64(len(batch)))
+	}
+}
+
+func (d *subscriptionDestination) post(url string, batch models.Points) error {
+	var buf bytes.Buffer
+	for _, p := range batch {
+		buf.WriteString(p.String())
+		buf.WriteByte('\n')
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if d.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+d.bearerToken)
+	} else if d.username != "" {
+		req.SetBasicAuth(d.username, d.password)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("received status code %d", resp.StatusCode)
+	}
+	return nil
+}
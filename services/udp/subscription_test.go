@@ -0,0 +1,106 @@
+package udp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"go.uber.org/zap"
+)
+
+func newTestSubscriptionDestination(t *testing.T, url string) *subscriptionDestination {
+	t.Helper()
+	dest, err := newSubscriptionDestination(SubscriptionConfig{
+		Name:         "test",
+		Protocol:     "http",
+		Destinations: []string{url},
+	})
+	if err != nil {
+		t.Fatalf("newSubscriptionDestination: %v", err)
+	}
+	return dest
+}
+
+// TestSubscriberIsolatesSlowDestination guards against a single slow or
+// unreachable destination stalling delivery to every other destination:
+// each destination must have its own queue and delivery goroutine.
+func TestSubscriberIsolatesSlowDestination(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slow.Close()
+
+	fastHit := make(chan struct{}, 1)
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fastHit <- struct{}{}
+	}))
+	defer fast.Close()
+
+	sub := &Subscriber{
+		closing: make(chan struct{}),
+		stats:   &Statistics{},
+		Logger:  zap.NewNop(),
+		destinations: []*subscriptionDestination{
+			newTestSubscriptionDestination(t, slow.URL),
+			newTestSubscriptionDestination(t, fast.URL),
+		},
+	}
+	sub.Open()
+	defer func() {
+		close(release)
+		sub.Close()
+	}()
+
+	batch := models.Points{}
+	sub.Send(batch)
+
+	select {
+	case <-fastHit:
+	case <-time.After(5 * time.Second):
+		t.Fatal("fast destination was never delivered to; the slow destination must be stalling it")
+	}
+}
+
+func TestSubscriberSendDoesNotBlockWhenQueueIsFull(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slow.Close()
+
+	sub := &Subscriber{
+		closing:      make(chan struct{}),
+		stats:        &Statistics{},
+		Logger:       zap.NewNop(),
+		destinations: []*subscriptionDestination{newTestSubscriptionDestination(t, slow.URL)},
+	}
+	sub.Open()
+	defer func() {
+		close(release)
+		sub.Close()
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < subscriberChanLen+10; i++ {
+			sub.Send(models.Points{})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Send blocked instead of dropping batches once the destination's queue filled")
+	}
+
+	if sub.stats.SubscriptionWriteFail == 0 {
+		t.Error("expected at least one dropped batch to be recorded")
+	}
+}
@@ -2,6 +2,7 @@ package authorization
 
 import (
 	"context"
+	"errors"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/authorizer"
@@ -11,15 +12,49 @@ type AuthFinder interface {
 	FindAuthorizationByID(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error)
 }
 
+// ErrPasswordChangeRateLimited is returned when changes to a user's
+// password have exceeded the configured RateLimiter for that user,
+// whoever is making them. HTTP handlers should
+// map this to a 429 response.
+var ErrPasswordChangeRateLimited = errors.New("too many password change attempts, please try again later")
+
 // AuthedPasswordService is middleware for authorizing requests to the inner PasswordService.
 type AuthedPasswordService struct {
-	auth  AuthFinder
-	inner PasswordService
+	auth    AuthFinder
+	inner   PasswordService
+	policy  PasswordPolicy
+	limiter RateLimiter
+}
+
+// AuthedPasswordServiceOption customizes an AuthedPasswordService.
+type AuthedPasswordServiceOption func(*AuthedPasswordService)
+
+// WithPasswordPolicy overrides the default PasswordPolicy, e.g. in tests.
+func WithPasswordPolicy(policy PasswordPolicy) AuthedPasswordServiceOption {
+	return func(s *AuthedPasswordService) { s.policy = policy }
+}
+
+// WithRateLimiter overrides the default RateLimiter, e.g. in tests.
+func WithRateLimiter(limiter RateLimiter) AuthedPasswordServiceOption {
+	return func(s *AuthedPasswordService) { s.limiter = limiter }
 }
 
 // NewAuthedPasswordService wraps an existing PasswordService with authorization middleware.
-func NewAuthedPasswordService(auth AuthFinder, inner PasswordService) *AuthedPasswordService {
-	return &AuthedPasswordService{auth: auth, inner: inner}
+// By default it enforces a 12-character minimum password policy and
+// limits each user to 5 password changes per minute; use WithPasswordPolicy
+// and WithRateLimiter to override either.
+func NewAuthedPasswordService(auth AuthFinder, inner PasswordService, opts ...AuthedPasswordServiceOption) *AuthedPasswordService {
+	policy, _ := NewDefaultPasswordPolicy(DefaultMinPasswordLength, "")
+	s := &AuthedPasswordService{
+		auth:    auth,
+		inner:   inner,
+		policy:  policy,
+		limiter: NewTokenBucketRateLimiter(DefaultRateLimit, DefaultRateLimitPeriod),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // SetPassword overrides the password of a known user.
@@ -33,10 +68,20 @@ func (s *AuthedPasswordService) SetPassword(ctx context.Context, authID influxdb
 		return err
 	}
 
+	if !s.limiter.Allow(auth.UserID) {
+		return ErrPasswordChangeRateLimited
+	}
+
+	if err := s.policy.Validate(password); err != nil {
+		return err
+	}
+
 	return s.inner.SetPassword(ctx, authID, password)
 }
 
-// SetPasswordHash overrides the password hash of a known user.
+// SetPasswordHash overrides the password hash of a known user. The
+// password policy cannot be enforced here, since only the hash (not the
+// plaintext password) is available, but the rate limit still applies.
 func (s *AuthedPasswordService) SetPasswordHash(ctx context.Context, authID influxdb.ID, password string) error {
 	auth, err := s.auth.FindAuthorizationByID(ctx, authID)
 	if err != nil {
@@ -47,5 +92,9 @@ func (s *AuthedPasswordService) SetPasswordHash(ctx context.Context, authID infl
 		return err
 	}
 
+	if !s.limiter.Allow(auth.UserID) {
+		return ErrPasswordChangeRateLimited
+	}
+
 	return s.inner.SetPasswordHash(ctx, authID, password)
 }
@@ -0,0 +1,181 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+)
+
+type fakeAuthFinder struct {
+	auth *influxdb.Authorization
+	err  error
+}
+
+func (f *fakeAuthFinder) FindAuthorizationByID(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+	return f.auth, f.err
+}
+
+type fakePasswordService struct {
+	setPasswordCalled     bool
+	setPasswordHashCalled bool
+}
+
+func (f *fakePasswordService) SetPassword(ctx context.Context, authID influxdb.ID, password string) error {
+	f.setPasswordCalled = true
+	return nil
+}
+
+func (f *fakePasswordService) SetPasswordHash(ctx context.Context, authID influxdb.ID, hash string) error {
+	f.setPasswordHashCalled = true
+	return nil
+}
+
+type fakePasswordPolicy struct {
+	called bool
+	err    error
+}
+
+func (f *fakePasswordPolicy) Validate(password string) error {
+	f.called = true
+	return f.err
+}
+
+type fakeRateLimiter struct {
+	called bool
+	allow  bool
+}
+
+func (f *fakeRateLimiter) Allow(userID influxdb.ID) bool {
+	f.called = true
+	return f.allow
+}
+
+func authedContext(userID influxdb.ID) context.Context {
+	return icontext.WithAuthorizer(context.Background(), &influxdb.Authorization{
+		UserID:      userID,
+		Permissions: influxdb.OperPermissions(),
+	})
+}
+
+func TestAuthedPasswordServiceSetPasswordChecksRateLimitBeforePolicy(t *testing.T) {
+	userID := influxdb.ID(1)
+	auth := &influxdb.Authorization{ID: 2, UserID: userID}
+	policy := &fakePasswordPolicy{}
+	limiter := &fakeRateLimiter{allow: false}
+	inner := &fakePasswordService{}
+
+	s := NewAuthedPasswordService(&fakeAuthFinder{auth: auth}, inner, WithPasswordPolicy(policy), WithRateLimiter(limiter))
+
+	err := s.SetPassword(authedContext(userID), auth.ID, "irrelevant")
+	if !errors.Is(err, ErrPasswordChangeRateLimited) {
+		t.Errorf("SetPassword() = %v, want %v", err, ErrPasswordChangeRateLimited)
+	}
+	if !limiter.called {
+		t.Error("expected the rate limiter to be consulted")
+	}
+	if policy.called {
+		t.Error("policy should not be validated once the rate limit has been hit")
+	}
+	if inner.setPasswordCalled {
+		t.Error("inner.SetPassword should not be called once the rate limit has been hit")
+	}
+}
+
+func TestAuthedPasswordServiceSetPasswordEnforcesPolicy(t *testing.T) {
+	userID := influxdb.ID(1)
+	auth := &influxdb.Authorization{ID: 2, UserID: userID}
+	policy := &fakePasswordPolicy{err: ErrPasswordTooWeak}
+	limiter := &fakeRateLimiter{allow: true}
+	inner := &fakePasswordService{}
+
+	s := NewAuthedPasswordService(&fakeAuthFinder{auth: auth}, inner, WithPasswordPolicy(policy), WithRateLimiter(limiter))
+
+	err := s.SetPassword(authedContext(userID), auth.ID, "weak")
+	if !errors.Is(err, ErrPasswordTooWeak) {
+		t.Errorf("SetPassword() = %v, want %v", err, ErrPasswordTooWeak)
+	}
+	if !policy.called {
+		t.Error("expected the password policy to be validated")
+	}
+	if inner.setPasswordCalled {
+		t.Error("inner.SetPassword should not be called once the policy rejects the password")
+	}
+}
+
+func TestAuthedPasswordServiceSetPasswordCallsInner(t *testing.T) {
+	userID := influxdb.ID(1)
+	auth := &influxdb.Authorization{ID: 2, UserID: userID}
+	policy := &fakePasswordPolicy{}
+	limiter := &fakeRateLimiter{allow: true}
+	inner := &fakePasswordService{}
+
+	s := NewAuthedPasswordService(&fakeAuthFinder{auth: auth}, inner, WithPasswordPolicy(policy), WithRateLimiter(limiter))
+
+	if err := s.SetPassword(authedContext(userID), auth.ID, "Valid1Password!"); err != nil {
+		t.Fatalf("SetPassword: %v", err)
+	}
+	if !inner.setPasswordCalled {
+		t.Error("expected inner.SetPassword to be called once the rate limit and policy both pass")
+	}
+}
+
+func TestAuthedPasswordServiceSetPasswordHashSkipsPolicy(t *testing.T) {
+	userID := influxdb.ID(1)
+	auth := &influxdb.Authorization{ID: 2, UserID: userID}
+	policy := &fakePasswordPolicy{err: ErrPasswordTooWeak}
+	limiter := &fakeRateLimiter{allow: true}
+	inner := &fakePasswordService{}
+
+	s := NewAuthedPasswordService(&fakeAuthFinder{auth: auth}, inner, WithPasswordPolicy(policy), WithRateLimiter(limiter))
+
+	if err := s.SetPasswordHash(authedContext(userID), auth.ID, "already-hashed"); err != nil {
+		t.Fatalf("SetPasswordHash: %v", err)
+	}
+	if policy.called {
+		t.Error("SetPasswordHash cannot validate a policy against a hash and should not try")
+	}
+	if !inner.setPasswordHashCalled {
+		t.Error("expected inner.SetPasswordHash to be called")
+	}
+}
+
+func TestAuthedPasswordServiceSetPasswordHashStillRateLimited(t *testing.T) {
+	userID := influxdb.ID(1)
+	auth := &influxdb.Authorization{ID: 2, UserID: userID}
+	limiter := &fakeRateLimiter{allow: false}
+	inner := &fakePasswordService{}
+
+	s := NewAuthedPasswordService(&fakeAuthFinder{auth: auth}, inner, WithRateLimiter(limiter))
+
+	err := s.SetPasswordHash(authedContext(userID), auth.ID, "already-hashed")
+	if !errors.Is(err, ErrPasswordChangeRateLimited) {
+		t.Errorf("SetPasswordHash() = %v, want %v", err, ErrPasswordChangeRateLimited)
+	}
+	if inner.setPasswordHashCalled {
+		t.Error("inner.SetPasswordHash should not be called once the rate limit has been hit")
+	}
+}
+
+func TestAuthedPasswordServiceAuthNotFound(t *testing.T) {
+	finder := &fakeAuthFinder{err: errors.New("no such authorization")}
+	s := NewAuthedPasswordService(finder, &fakePasswordService{})
+
+	if err := s.SetPassword(context.Background(), 1, "Valid1Password!"); !errors.Is(err, ErrAuthNotFound) {
+		t.Errorf("SetPassword() = %v, want %v", err, ErrAuthNotFound)
+	}
+}
+
+func TestNewAuthedPasswordServiceDefaults(t *testing.T) {
+	userID := influxdb.ID(1)
+	auth := &influxdb.Authorization{ID: 2, UserID: userID}
+	inner := &fakePasswordService{}
+
+	s := NewAuthedPasswordService(&fakeAuthFinder{auth: auth}, inner)
+
+	if err := s.SetPassword(authedContext(userID), auth.ID, "short"); !errors.Is(err, ErrPasswordTooShort) {
+		t.Errorf("SetPassword() with the default policy = %v, want %v", err, ErrPasswordTooShort)
+	}
+}
@@ -0,0 +1,101 @@
+package authorization
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"unicode"
+)
+
+// DefaultMinPasswordLength is the minimum password length enforced by
+// DefaultPasswordPolicy when none is specified.
+const DefaultMinPasswordLength = 12
+
+// ErrPasswordTooShort is returned when a candidate password is shorter
+// than the policy's minimum length.
+var ErrPasswordTooShort = errors.New("password does not meet minimum length requirements")
+
+// ErrPasswordTooWeak is returned when a candidate password does not mix
+// upper case, lower case, digit and symbol characters.
+var ErrPasswordTooWeak = errors.New("password must contain upper case, lower case, numeric and symbol characters")
+
+// ErrPasswordBreached is returned when a candidate password appears in
+// the configured deny-list of known-breached passwords.
+var ErrPasswordBreached = errors.New("password is known to be compromised and cannot be used")
+
+// PasswordPolicy validates a candidate password before it is stored.
+type PasswordPolicy interface {
+	Validate(password string) error
+}
+
+// DefaultPasswordPolicy enforces a minimum length, a mix of character
+// classes, and an optional deny-list of known-breached passwords loaded
+// from a file at startup.
+type DefaultPasswordPolicy struct {
+	MinLength int
+	denylist  map[string]struct{}
+}
+
+// NewDefaultPasswordPolicy returns a DefaultPasswordPolicy requiring at
+// least minLength characters. If denylistPath is non-empty, it is read
+// as a newline-separated list of disallowed passwords.
+func NewDefaultPasswordPolicy(minLength int, denylistPath string) (*DefaultPasswordPolicy, error) {
+	if minLength <= 0 {
+		minLength = DefaultMinPasswordLength
+	}
+
+	p := &DefaultPasswordPolicy{MinLength: minLength}
+	if denylistPath == "" {
+		return p, nil
+	}
+
+	f, err := os.Open(denylistPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening password deny-list: %w", err)
+	}
+	defer f.Close()
+
+	p.denylist = make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			p.denylist[line] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading password deny-list: %w", err)
+	}
+
+	return p, nil
+}
+
+// Validate implements PasswordPolicy.
+func (p *DefaultPasswordPolicy) Validate(password string) error {
+	if len(password) < p.MinLength {
+		return ErrPasswordTooShort
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+	if !hasUpper || !hasLower || !hasDigit || !hasSymbol {
+		return ErrPasswordTooWeak
+	}
+
+	if _, breached := p.denylist[password]; breached {
+		return ErrPasswordBreached
+	}
+
+	return nil
+}
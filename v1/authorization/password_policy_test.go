@@ -0,0 +1,73 @@
+package authorization
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultPasswordPolicyValidate(t *testing.T) {
+	policy, err := NewDefaultPasswordPolicy(DefaultMinPasswordLength, "")
+	if err != nil {
+		t.Fatalf("NewDefaultPasswordPolicy: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{name: "too short", password: "Aa1!aaa", wantErr: ErrPasswordTooShort},
+		{name: "missing upper case", password: "alllowercase1!", wantErr: ErrPasswordTooWeak},
+		{name: "missing lower case", password: "ALLUPPERCASE1!", wantErr: ErrPasswordTooWeak},
+		{name: "missing digit", password: "NoDigitsHere!!", wantErr: ErrPasswordTooWeak},
+		{name: "missing symbol", password: "NoSymbolsHere1", wantErr: ErrPasswordTooWeak},
+		{name: "meets all requirements", password: "Valid1Password!", wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate(%q) = %v, want %v", tt.password, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultPasswordPolicyValidateDenylist(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "denylist.txt")
+	if err := os.WriteFile(path, []byte("Breached1Password!\nAnother2Password!\n"), 0o600); err != nil {
+		t.Fatalf("writing denylist fixture: %v", err)
+	}
+
+	policy, err := NewDefaultPasswordPolicy(DefaultMinPasswordLength, path)
+	if err != nil {
+		t.Fatalf("NewDefaultPasswordPolicy: %v", err)
+	}
+
+	if err := policy.Validate("Breached1Password!"); !errors.Is(err, ErrPasswordBreached) {
+		t.Errorf("Validate(denylisted) = %v, want %v", err, ErrPasswordBreached)
+	}
+	if err := policy.Validate("NotBreached1Password!"); err != nil {
+		t.Errorf("Validate(not denylisted) = %v, want nil", err)
+	}
+}
+
+func TestNewDefaultPasswordPolicyMissingDenylist(t *testing.T) {
+	if _, err := NewDefaultPasswordPolicy(DefaultMinPasswordLength, filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Fatal("expected an error for a denylist path that does not exist, got nil")
+	}
+}
+
+func TestNewDefaultPasswordPolicyDefaultMinLength(t *testing.T) {
+	policy, err := NewDefaultPasswordPolicy(0, "")
+	if err != nil {
+		t.Fatalf("NewDefaultPasswordPolicy: %v", err)
+	}
+	if policy.MinLength != DefaultMinPasswordLength {
+		t.Errorf("MinLength = %d, want %d", policy.MinLength, DefaultMinPasswordLength)
+	}
+}
@@ -0,0 +1,80 @@
+package authorization
+
+import (
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// DefaultRateLimit and DefaultRateLimitPeriod bound how often a single
+// user's password may be changed via AuthedPasswordService, so that
+// brute-forcing a password change with a stolen admin token is bounded.
+const (
+	DefaultRateLimit       = 5
+	DefaultRateLimitPeriod = time.Minute
+)
+
+// RateLimiter decides whether a password-changing call for userID is
+// currently allowed.
+type RateLimiter interface {
+	Allow(userID influxdb.ID) bool
+}
+
+// TokenBucketRateLimiter is a per-user token bucket RateLimiter: each
+// user starts with a full bucket of limit tokens, which refill
+// continuously over period.
+type TokenBucketRateLimiter struct {
+	limit  int
+	period time.Duration
+
+	mu      sync.Mutex
+	buckets map[influxdb.ID]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucketRateLimiter returns a RateLimiter allowing up to limit
+// calls per period, per user.
+func NewTokenBucketRateLimiter(limit int, period time.Duration) *TokenBucketRateLimiter {
+	if limit <= 0 {
+		limit = DefaultRateLimit
+	}
+	if period <= 0 {
+		period = DefaultRateLimitPeriod
+	}
+	return &TokenBucketRateLimiter{
+		limit:   limit,
+		period:  period,
+		buckets: make(map[influxdb.ID]*tokenBucket),
+	}
+}
+
+// Allow implements RateLimiter.
+func (r *TokenBucketRateLimiter) Allow(userID influxdb.ID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	b, ok := r.buckets[userID]
+	if !ok {
+		b = &tokenBucket{tokens: float64(r.limit), last: now}
+		r.buckets[userID] = b
+	}
+
+	refill := now.Sub(b.last).Seconds() * (float64(r.limit) / r.period.Seconds())
+	b.tokens += refill
+	if b.tokens > float64(r.limit) {
+		b.tokens = float64(r.limit)
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
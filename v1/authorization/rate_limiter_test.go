@@ -0,0 +1,88 @@
+package authorization
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestTokenBucketRateLimiterAllowsUpToLimit(t *testing.T) {
+	r := NewTokenBucketRateLimiter(3, time.Minute)
+	userID := influxdb.ID(1)
+
+	for i := 0; i < 3; i++ {
+		if !r.Allow(userID) {
+			t.Fatalf("Allow() call %d = false, want true (within limit)", i+1)
+		}
+	}
+	if r.Allow(userID) {
+		t.Fatal("Allow() after exhausting the limit = true, want false")
+	}
+}
+
+func TestTokenBucketRateLimiterRefillsOverTime(t *testing.T) {
+	r := NewTokenBucketRateLimiter(2, time.Second)
+	userID := influxdb.ID(1)
+
+	if !r.Allow(userID) || !r.Allow(userID) {
+		t.Fatal("expected the first two calls to be allowed")
+	}
+	if r.Allow(userID) {
+		t.Fatal("expected the bucket to be empty after exhausting the limit")
+	}
+
+	// Simulate a full period elapsing without sleeping the test.
+	r.mu.Lock()
+	r.buckets[userID].last = time.Now().Add(-r.period)
+	r.mu.Unlock()
+
+	if !r.Allow(userID) {
+		t.Fatal("Allow() after a full period elapsed = false, want true (bucket should have refilled)")
+	}
+}
+
+func TestTokenBucketRateLimiterRefillDoesNotExceedLimit(t *testing.T) {
+	r := NewTokenBucketRateLimiter(2, time.Second)
+	userID := influxdb.ID(1)
+
+	r.Allow(userID)
+
+	// Simulate far more than a full period elapsing; tokens must still
+	// cap at the configured limit rather than accumulating unbounded.
+	r.mu.Lock()
+	r.buckets[userID].last = time.Now().Add(-time.Hour)
+	r.mu.Unlock()
+
+	if !r.Allow(userID) || !r.Allow(userID) {
+		t.Fatal("expected the bucket to have refilled to exactly the limit")
+	}
+	if r.Allow(userID) {
+		t.Fatal("bucket should not have refilled beyond the configured limit")
+	}
+}
+
+func TestTokenBucketRateLimiterPerUserIsolation(t *testing.T) {
+	r := NewTokenBucketRateLimiter(1, time.Minute)
+	userA, userB := influxdb.ID(1), influxdb.ID(2)
+
+	if !r.Allow(userA) {
+		t.Fatal("expected userA's first call to be allowed")
+	}
+	if r.Allow(userA) {
+		t.Fatal("expected userA's second call to be denied")
+	}
+	if !r.Allow(userB) {
+		t.Fatal("userB should have its own independent bucket")
+	}
+}
+
+func TestNewTokenBucketRateLimiterDefaults(t *testing.T) {
+	r := NewTokenBucketRateLimiter(0, 0)
+	if r.limit != DefaultRateLimit {
+		t.Errorf("limit = %d, want %d", r.limit, DefaultRateLimit)
+	}
+	if r.period != DefaultRateLimitPeriod {
+		t.Errorf("period = %s, want %s", r.period, DefaultRateLimitPeriod)
+	}
+}